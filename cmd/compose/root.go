@@ -0,0 +1,75 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"time"
+
+	"github.com/docker/compose/v2/pkg/selfupdate/updatecheck"
+	"github.com/spf13/cobra"
+)
+
+// updateCheckRepo and updateCheckInterval configure the background update
+// notifier every command but `selfupdate` itself runs via
+// PersistentPreRun/PersistentPostRun below.
+const (
+	updateCheckRepo     = "docker/compose"
+	updateCheckInterval = 24 * time.Hour
+)
+
+// NewRootCommand assembles the top-level `compose` command tree.
+func NewRootCommand() *cobra.Command {
+	var noUpdateCheck bool
+	var notify func()
+
+	cmd := &cobra.Command{
+		Use:   "compose",
+		Short: "Docker Compose",
+		// PersistentPreRun/PersistentPostRun implement the opt-in
+		// background update notifier: a stale check kicks off here in
+		// the background, and the banner (if the check completed in
+		// time and found a newer release) prints right before exit.
+		// `selfupdate` already checks for updates directly, so it
+		// skips this to avoid a redundant background request.
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			if cmd.Name() == "selfupdate" {
+				return
+			}
+			statePath, err := updatecheck.DefaultStatePath()
+			if err != nil {
+				return
+			}
+			notify = updatecheck.Start(updatecheck.Options{
+				Disabled:       noUpdateCheck,
+				Repo:           updateCheckRepo,
+				CurrentVersion: "2.2.2",
+				Interval:       updateCheckInterval,
+				StatePath:      statePath,
+			})
+		},
+		PersistentPostRun: func(cmd *cobra.Command, _ []string) {
+			if notify != nil {
+				notify()
+			}
+		},
+	}
+
+	cmd.PersistentFlags().BoolVar(&noUpdateCheck, "no-update-check", false, "Disable the background update notifier (also COMPOSE_DISABLE_UPDATE_CHECK)")
+	cmd.AddCommand(selfUpdateCommand())
+
+	return cmd
+}
@@ -17,42 +17,62 @@
 package compose
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/Masterminds/semver"
+	"github.com/docker/compose/v2/pkg/selfupdate"
+	"github.com/docker/compose/v2/pkg/selfupdate/archive"
+	"github.com/docker/compose/v2/pkg/selfupdate/provider"
+	"github.com/docker/compose/v2/pkg/selfupdate/verify"
 	"github.com/spf13/cobra"
 	"io"
 	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
-	"runtime"
+	"path/filepath"
+	"strconv"
 	"time"
 )
 
-type selfUpdateOptions struct {
-	unstable bool
-	quiet    bool
-}
-
-type VersionResponse struct {
-	Id      int    `json:"id"`
-	Url     string `json:"url"`
-	Name    string `json:"name,omitempty"`
-	TagName string `json:"tag_name" json:"tag_name,omitempty"`
+// init intercepts selfupdate.PostUpdateVerifyFlag before cobra ever builds
+// a command tree. Go runs every imported package's init() before main(),
+// so this fires no matter what entrypoint embeds this package, and the
+// freshly installed binary that selfupdate.ReplaceExecutable execs with
+// this flag exits 0 here instead of tripping cobra's "unknown flag"
+// rejection.
+func init() {
+	if len(os.Args) > 1 && os.Args[1] == selfupdate.PostUpdateVerifyFlag {
+		os.Exit(0)
+	}
 }
 
-type AssetsResponse struct {
-	Id   int    `json:"id"`
-	Url  string `json:"url"`
-	Name string `json:"name,omitempty"`
-}
+// buildTimeUnix is set at build time via
+//
+//	-ldflags "-X github.com/docker/compose/v2/cmd/compose.buildTimeUnix=..."
+//
+// It's compared against a release's published_at whenever the release ties
+// with this binary on semver, since nightlies can share a version number.
+var buildTimeUnix string
 
-type AssetFileResponse struct {
-	Size int    `json:"size"`
-	Url  string `json:"browser_download_url"`
+type selfUpdateOptions struct {
+	quiet              bool
+	source             string
+	repo               string
+	verify             string
+	track              string
+	version            string
+	nightlyManifestURL string
+	check              bool
+	dryRun             bool
+	output             string
+	providerConfig     string
+
+	// unstable is kept for backwards compatibility with earlier releases
+	// of selfupdate; prefer --track=unstable.
+	unstable bool
 }
 
 func selfUpdateCommand() *cobra.Command {
@@ -62,201 +82,401 @@ func selfUpdateCommand() *cobra.Command {
 		Short: "Install latest version of docker-compose",
 		Args:  cobra.MaximumNArgs(0),
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if opts.check || opts.dryRun {
+				os.Exit(runSelfUpdateCheck(opts))
+				return nil
+			}
 			runSelfUpdate(opts)
 			return nil
 		},
 	}
 
 	flags := cmd.Flags()
-	flags.BoolVar(&opts.unstable, "unstable", false, "Installs development version of docker-compose")
 	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Install updates without information messages")
+	flags.StringVar(&opts.source, "source", "github", "Release source: github, gitlab, manifest or dockerhub")
+	flags.StringVar(&opts.repo, "repo", "docker/compose", "Repository to fetch releases from (the manifest URL, for --source=manifest)")
+	flags.StringVar(&opts.verify, "verify", "sha256", "Verification required before installing: sha256, minisign or cosign")
+	flags.StringVar(&opts.track, "track", "stable", "Release channel to update from: stable, unstable or nightly")
+	flags.StringVar(&opts.version, "version", "", "Install this specific version instead of the latest on --track (can downgrade)")
+	flags.StringVar(&opts.nightlyManifestURL, "nightly-manifest-url", "https://download.docker.com/compose/nightly/manifest.json", "Manifest polled for --track=nightly")
+	flags.BoolVar(&opts.check, "check", false, "Check whether an update is available without installing it")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "Alias for --check")
+	flags.StringVar(&opts.output, "output", "text", "Output format for --check: text or json")
+	flags.StringVar(&opts.providerConfig, "provider-config", "", "Path to a JSON file configuring --source=manifest/dockerhub (manifest_signing_key, dockerhub_asset_url_template)")
+	flags.BoolVar(&opts.unstable, "unstable", false, "Installs development version of docker-compose")
+	_ = flags.MarkDeprecated("unstable", "use --track=unstable instead")
 
 	return cmd
 }
 
-func runSelfUpdate(opts selfUpdateOptions) {
-	fmt.Println("Checking for new docker-compose version ...")
+// releaseProviderConfig holds --source settings too sensitive or unwieldy
+// for a command-line flag: the manifest's own signing key and the Docker
+// Hub asset URL template. It's meant to be set once by whoever mirrors
+// compose, in a file pointed to by --provider-config, rather than typed on
+// every invocation.
+type releaseProviderConfig struct {
+	ManifestSigningKey        string `json:"manifest_signing_key"`
+	DockerHubAssetURLTemplate string `json:"dockerhub_asset_url_template"`
+}
+
+func loadReleaseProviderConfig(path string) (releaseProviderConfig, error) {
+	if path == "" {
+		return releaseProviderConfig{}, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return releaseProviderConfig{}, fmt.Errorf("reading --provider-config %q: %w", path, err)
+	}
+
+	var cfg releaseProviderConfig
+	if err := json.Unmarshal(content, &cfg); err != nil {
+		return releaseProviderConfig{}, fmt.Errorf("parsing --provider-config %q: %w", path, err)
+	}
+	return cfg, nil
+}
 
-	url := "https://api.github.com/repos/docker/compose/releases"
+// newReleaseProvider builds the ReleaseProvider backing --source (or the
+// nightly manifest, for --track=nightly), so users behind a corporate
+// mirror or running a fork can self-update without patching the binary.
+func newReleaseProvider(opts selfUpdateOptions, track string) (provider.ReleaseProvider, error) {
+	cfg, err := loadReleaseProviderConfig(opts.providerConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	body, err := requestJson(url)
+	if track == "nightly" {
+		return provider.NewManifestProvider(opts.nightlyManifestURL, cfg.ManifestSigningKey), nil
+	}
 
-	var versions []VersionResponse
-	jsonErr := json.Unmarshal(body, &versions)
-	if jsonErr != nil {
-		log.Fatal(jsonErr)
+	switch opts.source {
+	case "", "github":
+		return provider.NewGitHubProvider(opts.repo), nil
+	case "gitlab":
+		return provider.NewGitLabProvider("", opts.repo), nil
+	case "manifest":
+		return provider.NewManifestProvider(opts.repo, cfg.ManifestSigningKey), nil
+	case "dockerhub":
+		if cfg.DockerHubAssetURLTemplate == "" {
+			return nil, fmt.Errorf("--source=dockerhub requires dockerhub_asset_url_template set via --provider-config")
+		}
+		return provider.NewDockerHubProvider(opts.repo, cfg.DockerHubAssetURLTemplate), nil
+	default:
+		return nil, fmt.Errorf("unknown release source %q, must be one of github, gitlab, manifest, dockerhub", opts.source)
+	}
+}
+
+// shouldUpgrade decides whether release should replace the running
+// binary. Nightly builds can share a version number across commits, so a
+// semver tie is broken by comparing the release's published_at against
+// this binary's own build time.
+func shouldUpgrade(currentVersion *semver.Version, localBuildTime time.Time, release provider.Release) (bool, error) {
+	nextVersion, err := semver.NewVersion(release.Version)
+	if err != nil {
+		return false, fmt.Errorf("parsing version from release source: %w", err)
+	}
+
+	if nextVersion.GreaterThan(currentVersion) {
+		return true, nil
+	}
+	if nextVersion.LessThan(currentVersion) {
+		return false, nil
+	}
+
+	return !localBuildTime.IsZero() && !release.PublishedAt.IsZero() && release.PublishedAt.After(localBuildTime), nil
+}
+
+// resolvedTarget bundles what runSelfUpdate and runSelfUpdateCheck both
+// need once --source/--track/--version have been resolved against a
+// release source: the provider that produced it, the release itself, and
+// whether installing it would actually be an upgrade.
+type resolvedTarget struct {
+	provider       provider.ReleaseProvider
+	release        provider.Release
+	upgrade        bool
+	track          string
+	currentVersion string
+}
+
+// resolveTarget resolves --source/--track/--version down to a concrete
+// release and whether installing it would be an upgrade over the running
+// binary. It's the logic shared by runSelfUpdate and runSelfUpdateCheck:
+// only what happens with the result (install vs. report) differs between
+// them.
+func resolveTarget(ctx context.Context, opts selfUpdateOptions) (resolvedTarget, error) {
+	track := opts.track
+	if opts.unstable && track == "stable" {
+		track = "unstable"
+	}
+
+	releaseProvider, err := newReleaseProvider(opts, track)
+	if err != nil {
+		return resolvedTarget{}, err
 	}
 
 	version := "2.2.2"
 	//version := internal.Version
 	currentVersion, err := semver.NewVersion(version)
 	if err != nil {
-		log.Fatal("Failed to parse current Version", err)
+		return resolvedTarget{}, fmt.Errorf("failed to parse current version: %w", err)
 	}
 
-	fmt.Println("Latest tag is ", versions[0].Name)
+	var localBuildTime time.Time
+	if buildTimeUnix != "" {
+		sec, err := strconv.ParseInt(buildTimeUnix, 10, 64)
+		if err != nil {
+			return resolvedTarget{}, fmt.Errorf("failed to parse embedded build time: %w", err)
+		}
+		localBuildTime = time.Unix(sec, 0)
+	}
 
-	nextVersion, err := semver.NewVersion(versions[0].TagName)
+	var release provider.Release
+	if opts.version != "" {
+		release, err = releaseProvider.Release(ctx, opts.version)
+	} else {
+		release, err = releaseProvider.LatestRelease(ctx, track)
+	}
 	if err != nil {
-		log.Fatal("Failed to parse version from github releases", err)
+		return resolvedTarget{}, err
 	}
 
-	if nextVersion.LessThan(currentVersion) {
-		fmt.Println("Latest version is already installed")
-		return
+	upgrade := opts.version != ""
+	if !upgrade {
+		upgrade, err = shouldUpgrade(currentVersion, localBuildTime, release)
+		if err != nil {
+			return resolvedTarget{}, err
+		}
 	}
 
-	assetSuffix, err := matchArchitecture()
+	return resolvedTarget{
+		provider:       releaseProvider,
+		release:        release,
+		upgrade:        upgrade,
+		track:          track,
+		currentVersion: version,
+	}, nil
+}
+
+func runSelfUpdate(opts selfUpdateOptions) {
+	fmt.Println("Checking for new docker-compose version ...")
+
+	ctx := context.Background()
+	target, err := resolveTarget(ctx, opts)
 	if err != nil {
 		log.Fatal(err)
-		return
 	}
+	releaseProvider, release := target.provider, target.release
 
-	currentBinaryPath, err := os.Getwd()
-	if err != nil {
-		log.Fatal(err)
+	fmt.Println("Target tag is ", release.Tag)
+
+	if !target.upgrade {
+		fmt.Println("Latest version is already installed")
 		return
 	}
 
-	binaryFileUrl := fmt.Sprint("https://github.com/docker/compose/releases/download/v", nextVersion, "/docker-compose-", assetSuffix)
-	binaryFilePath, err := ioutil.TempFile(currentBinaryPath, "docker-compose-")
+	assetSuffix, err := archive.CurrentSuffix()
 	if err != nil {
 		log.Fatal(err)
+		return
 	}
 
-	err = downloadFile(binaryFileUrl, binaryFilePath)
+	currentBinaryPath, err := os.Executable()
 	if err != nil {
 		log.Fatal(err)
+		return
 	}
-
-	binarySHAFile, err := ioutil.TempFile(os.TempDir(), "docker-compose-*.sha256")
+	currentBinaryPath, err = filepath.EvalSymlinks(currentBinaryPath)
 	if err != nil {
 		log.Fatal(err)
+		return
 	}
 
-	err = downloadFile(binaryFileUrl+".sha256", binarySHAFile)
+	asset, checksum, err := releaseProvider.FetchAsset(ctx, release, assetSuffix)
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer asset.Close()
 
-	binaryFileContent, err := os.ReadFile(binaryFilePath.Name())
+	// Stage the download next to the running binary so the final rename in
+	// selfupdate.ReplaceExecutable stays on a single filesystem.
+	binaryFilePath, err := ioutil.TempFile(filepath.Dir(currentBinaryPath), "docker-compose-")
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	computedSum := sha256.Sum256(binaryFileContent)
-	downloadedSum, err := os.ReadFile(binarySHAFile.Name())
+	_, err = io.Copy(binaryFilePath, asset)
+	binaryFilePath.Close()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if hex.EncodeToString(computedSum[:]) != string(downloadedSum[:64]) {
-		fmt.Println("Sha256 hashes are not identically")
+	verifyMode := verify.Mode(opts.verify)
+
+	if checksum.Hex != "" {
+		binaryFileContent, err := os.ReadFile(binaryFilePath.Name())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		computedSum := sha256.Sum256(binaryFileContent)
+		if hex.EncodeToString(computedSum[:]) != checksum.Hex {
+			log.Fatal("Sha256 hashes do not match, refusing to install update")
+		}
+		fmt.Println("Sha256 hashes match")
+	} else if verifyMode == verify.ModeSHA256 {
+		// --verify=sha256 is the default, and several providers (gitlab,
+		// manifest, dockerhub) never publish a checksum alongside an
+		// asset. Installing unverified by default would defeat the point
+		// of --verify entirely, so refuse rather than silently skip.
+		log.Fatal("release source published no checksum; refusing to install an unverified update (use --verify=minisign or --verify=cosign for this source)")
+	} else {
+		fmt.Println("Release source published no checksum; relying on --verify=" + string(verifyMode) + " instead")
 	}
 
-	fmt.Println("Sha256 hashes identically. Replace current binary with new update")
+	if verifyMode != verify.ModeSHA256 {
+		if err := verifySignature(ctx, releaseProvider, release, assetSuffix, binaryFilePath.Name(), verifyMode); err != nil {
+			log.Fatal(fmt.Errorf("signature verification failed, refusing to install update: %w", err))
+		}
+		fmt.Println("Signature verified. Replacing current binary with new update")
+	} else {
+		fmt.Println("Replacing current binary with new update")
+	}
 
-	err = os.Rename(currentBinaryPath, currentBinaryPath+"_old")
+	installPath := binaryFilePath.Name()
+	format, err := archive.DetectFormat(installPath)
 	if err != nil {
 		log.Fatal(err)
-		return
+	}
+	if format != archive.FormatRaw {
+		extracted, err := archive.ExtractExecutable(format, installPath, filepath.Dir(currentBinaryPath))
+		if err != nil {
+			log.Fatal(fmt.Errorf("extracting binary from downloaded archive: %w", err))
+		}
+		os.Remove(installPath)
+		installPath = extracted
 	}
 
-	fmt.Printf("Move downdloaded file from %s to %s\n", binaryFilePath.Name(), currentBinaryPath+"_tmp")
-	err = os.Rename(binaryFilePath.Name(), currentBinaryPath+"_tmp")
-	if err != nil {
+	if err := selfupdate.ReplaceExecutable(installPath); err != nil {
 		log.Fatal(err)
 		return
 	}
-}
 
-func searchAssetUrl(err error, assets []AssetsResponse, s string) string {
-	assetIndex, err := searchAssets(assets, s)
-	assertUrl := assets[assetIndex].Url
-	if err != nil {
-		log.Fatal(err)
-	}
-	return assertUrl
+	fmt.Println("Update installed successfully")
 }
 
-func downloadFile(url string, file *os.File) error {
+// updateCheckResult is the --output=json shape for --check/--dry-run.
+type updateCheckResult struct {
+	CurrentVersion  string `json:"current_version"`
+	TargetVersion   string `json:"target_version"`
+	Track           string `json:"track"`
+	UpdateAvailable bool   `json:"update_available"`
+	AssetURL        string `json:"asset_url,omitempty"`
+	SHA256          string `json:"sha256,omitempty"`
+	Verification    string `json:"verification"`
+}
 
-	resp, err := http.Get(url)
+// runSelfUpdateCheck implements --check/--dry-run: it resolves the target
+// release exactly as runSelfUpdate does, but reports on it instead of
+// installing anything. It returns 0 if the running binary is already up to
+// date, or 1 if an update is available, so scripts can branch on exit code.
+func runSelfUpdateCheck(opts selfUpdateOptions) int {
+	ctx := context.Background()
+	target, err := resolveTarget(ctx, opts)
 	if err != nil {
-		return err
+		log.Fatal(err)
+	}
+	releaseProvider, release, upgrade := target.provider, target.release, target.upgrade
+
+	result := updateCheckResult{
+		CurrentVersion:  target.currentVersion,
+		TargetVersion:   release.Version,
+		Track:           target.track,
+		UpdateAvailable: upgrade,
+		Verification:    opts.verify,
 	}
 
-	defer resp.Body.Close()
-	defer file.Close()
+	if assetSuffix, err := archive.CurrentSuffix(); err == nil {
+		if asset, err := provider.FindAsset(release, assetSuffix); err == nil {
+			result.AssetURL = asset.DownloadURL
+		}
+		if upgrade {
+			// FetchAsset opens the download but we never read the body, so
+			// this costs a connection, not a transfer; it's the only way
+			// to learn the checksum a provider computes alongside it.
+			if body, checksum, err := releaseProvider.FetchAsset(ctx, release, assetSuffix); err == nil {
+				body.Close()
+				result.SHA256 = checksum.Hex
+			}
+		}
+	}
 
-	_, err = io.Copy(file, resp.Body)
-	if err != nil {
-		return err
+	if opts.output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Printf("Current version: %s\n", result.CurrentVersion)
+		fmt.Printf("Target version (%s): %s\n", result.Track, result.TargetVersion)
+		if result.AssetURL != "" {
+			fmt.Printf("Asset: %s\n", result.AssetURL)
+		}
+		if result.SHA256 != "" {
+			fmt.Printf("SHA256: %s\n", result.SHA256)
+		}
+		if result.UpdateAvailable {
+			fmt.Println("An update is available; run `compose selfupdate` to install it")
+		} else {
+			fmt.Println("Latest version is already installed")
+		}
 	}
 
-	return nil
+	if upgrade {
+		return 1
+	}
+	return 0
 }
 
-func requestJson(url string) ([]byte, error) {
-	client := http.Client{
-		Timeout: 30 * time.Second,
-	}
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+// verifySignature downloads the signature (and, for cosign, certificate
+// bundle) published alongside the release asset and checks it against the
+// key embedded in this binary at build time.
+func verifySignature(ctx context.Context, releaseProvider provider.ReleaseProvider, release provider.Release, platform, binaryPath string, mode verify.Mode) error {
+	binary, err := os.ReadFile(binaryPath)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	req.Header.Set("Accept", "application/vnd.github.v4+json")
-	res, err := client.Do(req)
+	sigReader, err := releaseProvider.FetchSignature(ctx, release, platform, ".sig")
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("fetching signature: %w", err)
 	}
-
-	if res.Body != nil {
-		defer res.Body.Close()
-	}
-
-	body, err := ioutil.ReadAll(res.Body)
+	signature, err := verify.ReadAll(sigReader)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return body, nil
-}
-
-func matchArchitecture() (string, error) {
-
-	if runtime.GOOS == "darwin" {
-		if runtime.GOARCH == "arm64" {
-			return "darwin-aarch64", nil
-		} else if runtime.GOARCH == "x86_64" {
-			return "darwin-x86_64", nil
+	var verifier verify.Verifier
+	switch mode {
+	case verify.ModeMinisign:
+		verifier = verify.MinisignVerifier{TrustedKey: verify.EmbeddedMinisignKey}
+		return verifier.Verify(ctx, binary, signature, nil)
+	case verify.ModeCosign:
+		certReader, err := releaseProvider.FetchSignature(ctx, release, platform, ".cert")
+		if err != nil {
+			return fmt.Errorf("fetching cosign certificate bundle: %w", err)
 		}
-	} else if runtime.GOOS == "linux" {
-		if runtime.GOARCH == "s390x" {
-			return "linux-s390x", nil
-		} else if runtime.GOARCH == "arm64" {
-			return "linux-aarch64", nil
-		} else if runtime.GOARCH == "" {
-			return "linux-armv6", nil
-		} else if runtime.GOARCH == "" {
-			return "linux-armv7", nil
-		} else if runtime.GOARCH == "amd64" {
-			return "linux-x86_64", nil
+		cert, err := verify.ReadAll(certReader)
+		if err != nil {
+			return err
 		}
-	} else if runtime.GOOS == "windows" && runtime.GOARCH == "x86_64" {
-		return "windows-x86_64", nil
-	}
-
-	return "", fmt.Errorf("no matching assets was found for %q and %q", runtime.GOOS, runtime.GOARCH)
-}
-
-func searchAssets(assets []AssetsResponse, needle string) (int, error) {
-	for i := range assets {
-		if assets[i].Name == needle {
-			return i, nil
+		verifier = verify.CosignVerifier{
+			PublicKey:             verify.EmbeddedCosignKey,
+			Keyless:               verify.EmbeddedCosignKey == "",
+			CertificateIdentity:   verify.EmbeddedCosignIdentity,
+			CertificateOIDCIssuer: verify.EmbeddedCosignIssuer,
 		}
+		return verifier.Verify(ctx, binary, signature, cert)
+	default:
+		return fmt.Errorf("unknown verification mode %q, must be one of sha256, minisign, cosign", mode)
 	}
-
-	return 0, fmt.Errorf("No matching asset was found")
 }
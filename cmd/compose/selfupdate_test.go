@@ -0,0 +1,90 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/docker/compose/v2/pkg/selfupdate/provider"
+)
+
+func mustVersion(t *testing.T, v string) *semver.Version {
+	t.Helper()
+	version, err := semver.NewVersion(v)
+	if err != nil {
+		t.Fatalf("parsing version %q: %v", v, err)
+	}
+	return version
+}
+
+func TestShouldUpgradeOnNewerSemver(t *testing.T) {
+	upgrade, err := shouldUpgrade(mustVersion(t, "2.24.0"), time.Time{}, provider.Release{Version: "2.25.0"})
+	if err != nil {
+		t.Fatalf("shouldUpgrade returned an error: %v", err)
+	}
+	if !upgrade {
+		t.Error("expected an upgrade to a strictly newer version")
+	}
+}
+
+func TestShouldUpgradeOnOlderSemver(t *testing.T) {
+	upgrade, err := shouldUpgrade(mustVersion(t, "2.24.0"), time.Time{}, provider.Release{Version: "2.23.0"})
+	if err != nil {
+		t.Fatalf("shouldUpgrade returned an error: %v", err)
+	}
+	if upgrade {
+		t.Error("expected no upgrade to an older version")
+	}
+}
+
+func TestShouldUpgradeBreaksSemverTieOnBuildTime(t *testing.T) {
+	localBuildTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newer, err := shouldUpgrade(mustVersion(t, "2.24.0-nightly"), localBuildTime, provider.Release{
+		Version:     "2.24.0-nightly",
+		PublishedAt: localBuildTime.Add(24 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("shouldUpgrade returned an error: %v", err)
+	}
+	if !newer {
+		t.Error("expected a semver tie to upgrade when the release was published after the local build")
+	}
+
+	older, err := shouldUpgrade(mustVersion(t, "2.24.0-nightly"), localBuildTime, provider.Release{
+		Version:     "2.24.0-nightly",
+		PublishedAt: localBuildTime.Add(-24 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("shouldUpgrade returned an error: %v", err)
+	}
+	if older {
+		t.Error("expected a semver tie to not upgrade when the release was published before the local build")
+	}
+}
+
+func TestShouldUpgradeTieWithoutBuildTimeInfo(t *testing.T) {
+	upgrade, err := shouldUpgrade(mustVersion(t, "2.24.0"), time.Time{}, provider.Release{Version: "2.24.0"})
+	if err != nil {
+		t.Fatalf("shouldUpgrade returned an error: %v", err)
+	}
+	if upgrade {
+		t.Error("expected no upgrade on a semver tie when neither build time is known")
+	}
+}
@@ -0,0 +1,146 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExtractExecutable locates the executable member of the archive at path
+// (already identified as format by DetectFormat) and streams it to a new
+// temp file in dir, returning its path. Compose's archives bundle a
+// single binary alongside non-executable files like LICENSE, so the
+// member is chosen by its executable bit, falling back to a name match
+// (".exe", or the "docker-compose" prefix) for archives — notably
+// Windows-built zips — whose central directory carries no Unix
+// permission bits at all.
+func ExtractExecutable(format Format, path, dir string) (string, error) {
+	switch format {
+	case FormatTarGZ, FormatTar:
+		f, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+
+		r := io.Reader(f)
+		if format == FormatTarGZ {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				return "", fmt.Errorf("opening gzip stream: %w", err)
+			}
+			defer gz.Close()
+			r = gz
+		}
+
+		return extractFromTar(tar.NewReader(r), dir)
+	case FormatZip:
+		return extractFromZip(path, dir)
+	default:
+		return "", fmt.Errorf("%q is not a recognized archive format", path)
+	}
+}
+
+func extractFromTar(tr *tar.Reader, dir string) (string, error) {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return "", fmt.Errorf("no executable member found in archive")
+		}
+		if err != nil {
+			return "", err
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.FileInfo().Mode()&0o111 == 0 {
+			continue
+		}
+		return streamToTempFile(tr, dir, filepath.Base(hdr.Name))
+	}
+}
+
+func extractFromZip(path, dir string) (string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("opening zip archive: %w", err)
+	}
+	defer zr.Close()
+
+	// Zips built on Windows routinely carry no Unix permission bits in
+	// their central directory, so the one .exe member often fails the
+	// executable-bit check below. Fall back to matching by name for any
+	// file the bit check didn't already accept.
+	var fallback *zip.File
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if f.Mode()&0o111 != 0 {
+			return extractZipMember(f, dir)
+		}
+		if fallback == nil && looksLikeExecutableName(f.Name) {
+			fallback = f
+		}
+	}
+
+	if fallback != nil {
+		return extractZipMember(fallback, dir)
+	}
+
+	return "", fmt.Errorf("no executable member found in archive")
+}
+
+// looksLikeExecutableName reports whether name matches the executable
+// compose publishes inside its archives, independent of the Unix exec bit.
+func looksLikeExecutableName(name string) bool {
+	base := filepath.Base(name)
+	if strings.EqualFold(filepath.Ext(base), ".exe") {
+		return true
+	}
+	return strings.HasPrefix(base, "docker-compose")
+}
+
+func extractZipMember(f *zip.File, dir string) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	return streamToTempFile(rc, dir, filepath.Base(f.Name))
+}
+
+// streamToTempFile copies r to a new temp file in dir without holding its
+// contents in memory, returning the file's path.
+func streamToTempFile(r io.Reader, dir, name string) (string, error) {
+	f, err := os.CreateTemp(dir, name+"-*")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
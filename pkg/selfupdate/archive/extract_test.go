@@ -0,0 +1,144 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTar builds an in-memory tar archive from entries, where a leading
+// "x:" on a name marks that member executable.
+func writeTar(t *testing.T, entries map[string]string) *tar.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range entries {
+		mode := int64(0o644)
+		if strings.HasPrefix(name, "x:") {
+			name = strings.TrimPrefix(name, "x:")
+			mode = 0o755
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: mode, Size: int64(len(content))}); err != nil {
+			t.Fatalf("writing tar header for %q: %v", name, err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("writing tar content for %q: %v", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar writer: %v", err)
+	}
+	return tar.NewReader(&buf)
+}
+
+func TestExtractFromTarSelectsExecutableMember(t *testing.T) {
+	dir := t.TempDir()
+	tr := writeTar(t, map[string]string{
+		"LICENSE":          "license text",
+		"x:docker-compose": "binary content",
+	})
+
+	extracted, err := extractFromTar(tr, dir)
+	if err != nil {
+		t.Fatalf("extractFromTar returned an error: %v", err)
+	}
+	defer os.Remove(extracted)
+
+	content, err := os.ReadFile(extracted)
+	if err != nil {
+		t.Fatalf("reading extracted file: %v", err)
+	}
+	if string(content) != "binary content" {
+		t.Errorf("extracted %q, want the executable member's content", string(content))
+	}
+}
+
+func TestExtractFromTarNoExecutableMember(t *testing.T) {
+	dir := t.TempDir()
+	tr := writeTar(t, map[string]string{"LICENSE": "license text"})
+
+	if _, err := extractFromTar(tr, dir); err == nil {
+		t.Error("expected an error when no member looks executable, got nil")
+	}
+}
+
+// writeZip builds a zip at dir/archive.zip with no Unix permission bits set
+// on any entry, mirroring what a Windows-built archive's central
+// directory looks like.
+func writeZip(t *testing.T, dir string, entries map[string]string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "archive.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("creating zip: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("adding %q to zip: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("writing %q: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("closing zip: %v", err)
+	}
+	return path
+}
+
+func TestExtractFromZipFallsBackToNameWithoutExecBit(t *testing.T) {
+	dir := t.TempDir()
+	path := writeZip(t, dir, map[string]string{
+		"LICENSE":            "license text",
+		"docker-compose.exe": "binary content",
+	})
+
+	extracted, err := extractFromZip(path, dir)
+	if err != nil {
+		t.Fatalf("extractFromZip returned an error: %v", err)
+	}
+	defer os.Remove(extracted)
+
+	content, err := os.ReadFile(extracted)
+	if err != nil {
+		t.Fatalf("reading extracted file: %v", err)
+	}
+	if string(content) != "binary content" {
+		t.Errorf("extracted %q, want the .exe member's content", string(content))
+	}
+}
+
+func TestExtractFromZipNoExecutableMember(t *testing.T) {
+	dir := t.TempDir()
+	path := writeZip(t, dir, map[string]string{"LICENSE": "license text"})
+
+	if _, err := extractFromZip(path, dir); err == nil {
+		t.Error("expected an error when no member looks executable, got nil")
+	}
+}
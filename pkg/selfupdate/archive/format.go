@@ -0,0 +1,62 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package archive
+
+import (
+	"bytes"
+	"os"
+)
+
+// Format identifies how a downloaded release asset is packaged.
+type Format int
+
+const (
+	// FormatRaw is a bare executable, compose's original release shape.
+	FormatRaw Format = iota
+	FormatTarGZ
+	FormatTar
+	FormatZip
+)
+
+// DetectFormat content-sniffs path by magic bytes rather than trusting a
+// file extension, since release assets downloaded by URL don't reliably
+// carry one.
+func DetectFormat(path string) (Format, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return FormatRaw, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 262)
+	n, err := f.Read(header)
+	if err != nil && n == 0 {
+		return FormatRaw, err
+	}
+	header = header[:n]
+
+	switch {
+	case len(header) >= 2 && header[0] == 0x1f && header[1] == 0x8b:
+		return FormatTarGZ, nil
+	case len(header) >= 4 && bytes.Equal(header[:4], []byte{'P', 'K', 0x03, 0x04}):
+		return FormatZip, nil
+	case len(header) >= 262 && bytes.Equal(header[257:262], []byte("ustar")):
+		return FormatTar, nil
+	default:
+		return FormatRaw, nil
+	}
+}
@@ -0,0 +1,93 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile writes content to dir/name and returns its path.
+func writeFile(t *testing.T, dir, name string, content []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("writing %q: %v", name, err)
+	}
+	return path
+}
+
+func TestDetectFormat(t *testing.T) {
+	dir := t.TempDir()
+
+	var gzBuf bytes.Buffer
+	gz := gzip.NewWriter(&gzBuf)
+	if _, err := gz.Write([]byte("content")); err != nil {
+		t.Fatalf("writing gzip stream: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("closing gzip stream: %v", err)
+	}
+
+	var tarBuf bytes.Buffer
+	tw := tar.NewWriter(&tarBuf)
+	if err := tw.WriteHeader(&tar.Header{Name: "docker-compose", Mode: 0o755, Size: int64(len("binary"))}); err != nil {
+		t.Fatalf("writing tar header: %v", err)
+	}
+	if _, err := tw.Write([]byte("binary")); err != nil {
+		t.Fatalf("writing tar content: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar writer: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    Format
+	}{
+		{"tar.gz", gzBuf.Bytes(), FormatTarGZ},
+		{"zip", []byte{'P', 'K', 0x03, 0x04, 0x00, 0x00}, FormatZip},
+		{"tar", tarBuf.Bytes(), FormatTar},
+		{"raw", []byte("#!/bin/sh\necho hi\n"), FormatRaw},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, dir, tt.name, tt.content)
+
+			got, err := DetectFormat(path)
+			if err != nil {
+				t.Fatalf("DetectFormat(%q) returned an error: %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectFormat(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectFormatMissingFile(t *testing.T) {
+	if _, err := DetectFormat(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing file, got nil")
+	}
+}
@@ -0,0 +1,85 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package archive detects the release asset suffix for the running
+// platform and extracts the compose binary from whatever format that
+// asset is packaged in (a raw binary, or a tar/tar.gz/zip archive).
+package archive
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Platform identifies a published release asset suffix, such as
+// "linux-x86_64" or "linux-armv7".
+type Platform struct {
+	GOOS   string
+	GOARCH string
+	// GOARM distinguishes "linux/arm/v6" from "linux/arm/v7" builds; it's
+	// only meaningful (and only ever populated) when GOARCH is "arm".
+	GOARM string
+}
+
+// platformSuffixes maps every (GOOS, GOARCH[, GOARM]) triple compose
+// publishes to the suffix used in its release asset names.
+var platformSuffixes = map[Platform]string{
+	{GOOS: "darwin", GOARCH: "amd64"}:          "darwin-x86_64",
+	{GOOS: "darwin", GOARCH: "arm64"}:          "darwin-aarch64",
+	{GOOS: "linux", GOARCH: "amd64"}:           "linux-x86_64",
+	{GOOS: "linux", GOARCH: "arm64"}:           "linux-aarch64",
+	{GOOS: "linux", GOARCH: "s390x"}:           "linux-s390x",
+	{GOOS: "linux", GOARCH: "ppc64le"}:         "linux-ppc64le",
+	{GOOS: "linux", GOARCH: "arm", GOARM: "6"}: "linux-armv6",
+	{GOOS: "linux", GOARCH: "arm", GOARM: "7"}: "linux-armv7",
+	{GOOS: "windows", GOARCH: "amd64"}:         "windows-x86_64",
+}
+
+// CurrentSuffix returns the release asset suffix published for the
+// platform this binary is currently running on.
+func CurrentSuffix() (string, error) {
+	return SuffixFor(Platform{GOOS: runtime.GOOS, GOARCH: runtime.GOARCH, GOARM: currentGOARM()})
+}
+
+// SuffixFor returns the release asset suffix compose publishes for p.
+func SuffixFor(p Platform) (string, error) {
+	if p.GOARCH != "arm" {
+		p.GOARM = ""
+	}
+
+	suffix, ok := platformSuffixes[p]
+	if !ok {
+		return "", fmt.Errorf("no matching release asset for GOOS=%q GOARCH=%q GOARM=%q", p.GOOS, p.GOARCH, p.GOARM)
+	}
+	return suffix, nil
+}
+
+// currentGOARM reads the GOARM the running binary was built with. It's not
+// exposed via the runtime package directly, only through the build info
+// embedded by the Go toolchain.
+func currentGOARM() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "GOARM" {
+			return setting.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package archive
+
+import "testing"
+
+func TestSuffixForEveryPublishedTriple(t *testing.T) {
+	tests := []struct {
+		platform Platform
+		suffix   string
+	}{
+		{Platform{GOOS: "darwin", GOARCH: "amd64"}, "darwin-x86_64"},
+		{Platform{GOOS: "darwin", GOARCH: "arm64"}, "darwin-aarch64"},
+		{Platform{GOOS: "linux", GOARCH: "amd64"}, "linux-x86_64"},
+		{Platform{GOOS: "linux", GOARCH: "arm64"}, "linux-aarch64"},
+		{Platform{GOOS: "linux", GOARCH: "s390x"}, "linux-s390x"},
+		{Platform{GOOS: "linux", GOARCH: "ppc64le"}, "linux-ppc64le"},
+		{Platform{GOOS: "linux", GOARCH: "arm", GOARM: "6"}, "linux-armv6"},
+		{Platform{GOOS: "linux", GOARCH: "arm", GOARM: "7"}, "linux-armv7"},
+		{Platform{GOOS: "windows", GOARCH: "amd64"}, "windows-x86_64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.suffix, func(t *testing.T) {
+			got, err := SuffixFor(tt.platform)
+			if err != nil {
+				t.Fatalf("SuffixFor(%+v) returned error: %v", tt.platform, err)
+			}
+			if got != tt.suffix {
+				t.Errorf("SuffixFor(%+v) = %q, want %q", tt.platform, got, tt.suffix)
+			}
+		})
+	}
+}
+
+func TestSuffixForIgnoresGOARMOutsideARM(t *testing.T) {
+	// GOARM is meaningless for non-arm architectures; a stray value there
+	// shouldn't stop the amd64 entry from matching.
+	got, err := SuffixFor(Platform{GOOS: "linux", GOARCH: "amd64", GOARM: "7"})
+	if err != nil {
+		t.Fatalf("SuffixFor returned error: %v", err)
+	}
+	if got != "linux-x86_64" {
+		t.Errorf("SuffixFor = %q, want %q", got, "linux-x86_64")
+	}
+}
+
+func TestSuffixForUnknownPlatform(t *testing.T) {
+	if _, err := SuffixFor(Platform{GOOS: "plan9", GOARCH: "amd64"}); err == nil {
+		t.Error("expected an error for an unpublished platform, got nil")
+	}
+}
@@ -0,0 +1,168 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DockerHubProvider treats Docker Hub image tags as releases, for forks
+// that publish the compose binary as an asset attached to an image build
+// rather than a VCS release. It walks
+// https://hub.docker.com/v2/repositories/<repo>/tags the same way `docker
+// pull` tooling does.
+type DockerHubProvider struct {
+	// Repo is a Docker Hub repository, e.g. "someorg/compose-builds".
+	Repo string
+	// AssetURLTemplate is formatted with the tag name to produce the
+	// download URL, since Docker Hub doesn't expose release assets
+	// directly. e.g. "https://dl.example.com/compose/%s/docker-compose-%s"
+	// (tag, then platform).
+	AssetURLTemplate string
+
+	httpClient *http.Client
+}
+
+// NewDockerHubProvider returns a provider listing tags for repo.
+func NewDockerHubProvider(repo, assetURLTemplate string) *DockerHubProvider {
+	return &DockerHubProvider{
+		Repo:             repo,
+		AssetURLTemplate: assetURLTemplate,
+		httpClient:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type dockerHubTagsResponse struct {
+	Results []dockerHubTag `json:"results"`
+}
+
+type dockerHubTag struct {
+	Name        string    `json:"name"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+func (p *DockerHubProvider) LatestRelease(ctx context.Context, track string) (Release, error) {
+	url := fmt.Sprintf("https://hub.docker.com/v2/repositories/%s/tags?page_size=100&ordering=last_updated", p.Repo)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Release{}, err
+	}
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return Release{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("GET %s: unexpected status %s", url, res.Status)
+	}
+
+	var tags dockerHubTagsResponse
+	if err := json.NewDecoder(res.Body).Decode(&tags); err != nil {
+		return Release{}, err
+	}
+
+	for _, t := range tags.Results {
+		if t.Name == "latest" {
+			continue
+		}
+		if track == "nightly" != isNightlyTag(t.Name) {
+			continue
+		}
+		return Release{
+			Version:     t.Name,
+			Tag:         t.Name,
+			Prerelease:  isNightlyTag(t.Name),
+			PublishedAt: t.LastUpdated,
+		}, nil
+	}
+
+	return Release{}, fmt.Errorf("no %s tag found in Docker Hub repository %s", track, p.Repo)
+}
+
+// Release returns the release tagged version, for --version pin/downgrade
+// support.
+func (p *DockerHubProvider) Release(ctx context.Context, version string) (Release, error) {
+	url := fmt.Sprintf("https://hub.docker.com/v2/repositories/%s/tags/%s", p.Repo, version)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Release{}, err
+	}
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return Release{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("GET %s: unexpected status %s", url, res.Status)
+	}
+
+	var tag dockerHubTag
+	if err := json.NewDecoder(res.Body).Decode(&tag); err != nil {
+		return Release{}, err
+	}
+
+	return Release{
+		Version:     tag.Name,
+		Tag:         tag.Name,
+		Prerelease:  isNightlyTag(tag.Name),
+		PublishedAt: tag.LastUpdated,
+	}, nil
+}
+
+func (p *DockerHubProvider) FetchAsset(ctx context.Context, release Release, platform string) (io.ReadCloser, Checksum, error) {
+	if p.AssetURLTemplate == "" {
+		return nil, Checksum{}, fmt.Errorf("dockerhub provider requires AssetURLTemplate to locate the binary for a tag")
+	}
+	assetURL := fmt.Sprintf(p.AssetURLTemplate, release.Tag, platform)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, assetURL, nil)
+	if err != nil {
+		return nil, Checksum{}, err
+	}
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, Checksum{}, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, Checksum{}, fmt.Errorf("GET %s: unexpected status %s", assetURL, res.Status)
+	}
+
+	return res.Body, Checksum{}, nil
+}
+
+func (p *DockerHubProvider) FetchSignature(ctx context.Context, release Release, platform, suffix string) (io.ReadCloser, error) {
+	if p.AssetURLTemplate == "" {
+		return nil, fmt.Errorf("dockerhub provider requires AssetURLTemplate to locate the binary for a tag")
+	}
+	assetURL := fmt.Sprintf(p.AssetURLTemplate, release.Tag, platform)
+	return fetchSibling(ctx, p.httpClient, assetURL, suffix)
+}
+
+func isNightlyTag(tag string) bool {
+	return len(tag) > 7 && tag[:7] == "nightly"
+}
@@ -0,0 +1,210 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/docker/compose/v2/pkg/selfupdate/verify"
+)
+
+// ManifestProvider reads releases from a static JSON document served over
+// HTTPS, for users behind a corporate mirror who can't (or don't want to)
+// reach GitHub/GitLab directly. The manifest format is:
+//
+//	{
+//	  "releases": [
+//	    {
+//	      "version": "2.24.0",
+//	      "published_at": "2024-01-10T12:00:00Z",
+//	      "prerelease": false,
+//	      "assets": [
+//	        {"name": "docker-compose-linux-x86_64", "url": "https://.../docker-compose-linux-x86_64", "size": 12345}
+//	      ]
+//	    }
+//	  ]
+//	}
+type ManifestProvider struct {
+	// URL points at the manifest JSON document.
+	URL string
+	// SigningKey, when set, is the base64 Ed25519 public key used to
+	// verify a detached ".minisig" signature published alongside the
+	// manifest itself (not the binary, which is covered separately by
+	// --verify).
+	SigningKey string
+
+	httpClient *http.Client
+}
+
+// NewManifestProvider returns a provider reading releases from url.
+func NewManifestProvider(url, signingKey string) *ManifestProvider {
+	return &ManifestProvider{
+		URL:        url,
+		SigningKey: signingKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type manifestDocument struct {
+	Releases []manifestRelease `json:"releases"`
+}
+
+type manifestRelease struct {
+	Version     string          `json:"version"`
+	PublishedAt time.Time       `json:"published_at"`
+	Prerelease  bool            `json:"prerelease"`
+	Assets      []manifestAsset `json:"assets"`
+}
+
+type manifestAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Size int64  `json:"size"`
+}
+
+func (p *ManifestProvider) LatestRelease(ctx context.Context, track string) (Release, error) {
+	releases, err := p.listReleases(ctx)
+	if err != nil {
+		return Release{}, err
+	}
+
+	for _, r := range releases {
+		if track == "stable" && r.Prerelease {
+			continue
+		}
+		return manifestToRelease(r), nil
+	}
+
+	return Release{}, fmt.Errorf("no %s release found in manifest %s", track, p.URL)
+}
+
+// Release returns the release tagged version (e.g. "2.24.0"), for
+// --version pin/downgrade support.
+func (p *ManifestProvider) Release(ctx context.Context, version string) (Release, error) {
+	releases, err := p.listReleases(ctx)
+	if err != nil {
+		return Release{}, err
+	}
+
+	for _, r := range releases {
+		if r.Version == version {
+			return manifestToRelease(r), nil
+		}
+	}
+
+	return Release{}, fmt.Errorf("no release %q found in manifest %s", version, p.URL)
+}
+
+func (p *ManifestProvider) listReleases(ctx context.Context) ([]manifestRelease, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: unexpected status %s", p.URL, res.Status)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.SigningKey != "" {
+		if err := p.verifyManifestSignature(ctx, body); err != nil {
+			return nil, fmt.Errorf("verifying manifest signature: %w", err)
+		}
+	}
+
+	var doc manifestDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Releases, nil
+}
+
+// verifyManifestSignature checks the manifest document itself (not a
+// binary) against a detached ".minisig" published alongside it, so a
+// compromised mirror can't silently rewrite which releases/assets this
+// provider resolves.
+func (p *ManifestProvider) verifyManifestSignature(ctx context.Context, body []byte) error {
+	sigReader, err := fetchSibling(ctx, p.httpClient, p.URL, ".minisig")
+	if err != nil {
+		return fmt.Errorf("fetching manifest signature: %w", err)
+	}
+	signature, err := verify.ReadAll(sigReader)
+	if err != nil {
+		return err
+	}
+	verifier := verify.MinisignVerifier{TrustedKey: p.SigningKey}
+	return verifier.Verify(ctx, body, signature, nil)
+}
+
+func manifestToRelease(r manifestRelease) Release {
+	assets := make([]Asset, 0, len(r.Assets))
+	for _, a := range r.Assets {
+		assets = append(assets, Asset{Name: a.Name, DownloadURL: a.URL, Size: a.Size})
+	}
+	return Release{
+		Version:     r.Version,
+		Tag:         r.Version,
+		Prerelease:  r.Prerelease,
+		PublishedAt: r.PublishedAt,
+		Assets:      assets,
+	}
+}
+
+func (p *ManifestProvider) FetchAsset(ctx context.Context, release Release, platform string) (io.ReadCloser, Checksum, error) {
+	asset, err := FindAsset(release, platform)
+	if err != nil {
+		return nil, Checksum{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.DownloadURL, nil)
+	if err != nil {
+		return nil, Checksum{}, err
+	}
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, Checksum{}, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, Checksum{}, fmt.Errorf("GET %s: unexpected status %s", asset.DownloadURL, res.Status)
+	}
+
+	return res.Body, Checksum{}, nil
+}
+
+func (p *ManifestProvider) FetchSignature(ctx context.Context, release Release, platform, suffix string) (io.ReadCloser, error) {
+	asset, err := FindAsset(release, platform)
+	if err != nil {
+		return nil, err
+	}
+	return fetchSibling(ctx, p.httpClient, asset.DownloadURL, suffix)
+}
@@ -0,0 +1,131 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package provider abstracts over the places a docker-compose release can
+// be fetched from, so that `compose selfupdate` isn't hardcoded to GitHub.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Release describes a single published version of docker-compose,
+// independent of which backend it came from.
+type Release struct {
+	// Version is the semver-parseable version string, without any leading "v".
+	Version     string
+	// Tag is the backend's native identifier for this release (e.g. a git
+	// tag, or a Docker Hub tag name).
+	Tag         string
+	Prerelease  bool
+	Draft       bool
+	PublishedAt time.Time
+	Assets      []Asset
+}
+
+// Asset is a single downloadable file attached to a Release.
+type Asset struct {
+	Name        string
+	DownloadURL string
+	Size        int64
+}
+
+// Checksum is the expected digest of a downloaded asset.
+type Checksum struct {
+	// Algorithm is almost always "sha256".
+	Algorithm string
+	// Hex is the lowercase hex-encoded digest.
+	Hex string
+}
+
+// ReleaseProvider is implemented by each backend compose can fetch updates
+// from (GitHub, GitLab, a static manifest, Docker Hub, ...).
+type ReleaseProvider interface {
+	// LatestRelease returns the newest release on the given track
+	// ("stable", "unstable", "nightly"). Providers that don't distinguish
+	// tracks may ignore the argument and always return their single feed.
+	LatestRelease(ctx context.Context, track string) (Release, error)
+	// Release returns the specific release tagged version (e.g.
+	// "2.24.0"), for --version pin/downgrade support.
+	Release(ctx context.Context, version string) (Release, error)
+	// FetchAsset opens the release asset matching platform (e.g.
+	// "linux-x86_64") and returns its expected checksum alongside it, when
+	// the backend publishes one.
+	FetchAsset(ctx context.Context, release Release, platform string) (io.ReadCloser, Checksum, error)
+	// FetchSignature fetches a file published alongside the release asset
+	// for platform, such as ".sig" or ".cert", for signature verification
+	// modes beyond a plain checksum. It errors if the backend has no such
+	// file for this asset.
+	FetchSignature(ctx context.Context, release Release, platform, suffix string) (io.ReadCloser, error)
+}
+
+// siblingSuffixes lists the extensions published alongside a release
+// asset for checksum/signature verification (see verify and fetchSibling
+// below). FindAsset excludes them so it can't hand back a ".sha256" or
+// ".sig" file whose name also happens to contain the platform substring.
+var siblingSuffixes = []string{".sha256", ".sig", ".cert", ".minisig", ".asc"}
+
+// FindAsset returns the asset in release whose name contains platform, or
+// an error if none matches. It's a small helper shared by the providers
+// below, which all locate assets by substring match on the platform
+// suffix compose publishes (e.g. "linux-x86_64").
+func FindAsset(release Release, platform string) (Asset, error) {
+	for _, asset := range release.Assets {
+		if !strings.Contains(asset.Name, platform) {
+			continue
+		}
+		if hasSiblingSuffix(asset.Name) {
+			continue
+		}
+		return asset, nil
+	}
+	return Asset{}, fmt.Errorf("no asset matching platform %q in release %q", platform, release.Tag)
+}
+
+func hasSiblingSuffix(name string) bool {
+	for _, suffix := range siblingSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchSibling fetches baseURL+suffix (e.g. the ".sig" or ".cert" file
+// published next to a release asset). It's shared by the providers below,
+// which all publish such files, if at all, as plain sibling URLs.
+func fetchSibling(ctx context.Context, client *http.Client, baseURL, suffix string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+suffix, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("GET %s: unexpected status %s", baseURL+suffix, res.Status)
+	}
+
+	return res.Body, nil
+}
@@ -0,0 +1,64 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import "testing"
+
+func TestFindAssetSkipsSiblingChecksumAndSignatureFiles(t *testing.T) {
+	release := Release{
+		Tag: "v2.24.0",
+		Assets: []Asset{
+			{Name: "docker-compose-linux-x86_64.sha256"},
+			{Name: "docker-compose-linux-x86_64.sig"},
+			{Name: "docker-compose-linux-x86_64.cert"},
+			{Name: "docker-compose-linux-x86_64"},
+		},
+	}
+
+	asset, err := FindAsset(release, "linux-x86_64")
+	if err != nil {
+		t.Fatalf("FindAsset returned an error: %v", err)
+	}
+	if asset.Name != "docker-compose-linux-x86_64" {
+		t.Errorf("FindAsset = %q, want the actual binary asset", asset.Name)
+	}
+}
+
+func TestFindAssetSkipsSiblingsRegardlessOfOrder(t *testing.T) {
+	release := Release{
+		Tag: "v2.24.0",
+		Assets: []Asset{
+			{Name: "docker-compose-linux-x86_64"},
+			{Name: "docker-compose-linux-x86_64.sha256"},
+		},
+	}
+
+	asset, err := FindAsset(release, "linux-x86_64")
+	if err != nil {
+		t.Fatalf("FindAsset returned an error: %v", err)
+	}
+	if asset.Name != "docker-compose-linux-x86_64" {
+		t.Errorf("FindAsset = %q, want the actual binary asset", asset.Name)
+	}
+}
+
+func TestFindAssetNoMatch(t *testing.T) {
+	release := Release{Tag: "v2.24.0", Assets: []Asset{{Name: "docker-compose-darwin-x86_64"}}}
+	if _, err := FindAsset(release, "linux-x86_64"); err == nil {
+		t.Error("expected an error when no asset matches the platform, got nil")
+	}
+}
@@ -0,0 +1,110 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package selfupdate implements the mechanics of replacing the currently
+// running docker-compose binary on disk.
+package selfupdate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// PostUpdateVerifyFlag is passed to the freshly installed binary so that it
+// exits 0 immediately instead of running the normal CLI. It must be
+// intercepted before cobra ever sees it, or cobra rejects it as an unknown
+// flag and every update fails verification; cmd/compose does this from an
+// init() function, which Go guarantees runs before main() regardless of
+// what embeds this package as its entrypoint.
+const PostUpdateVerifyFlag = "--post-update-verify"
+
+// VerifyTimeout bounds how long the post-update verification child process
+// may run before the update is considered failed and rolled back.
+const VerifyTimeout = 10 * time.Second
+
+// ReplaceExecutable atomically swaps the currently running binary for
+// newBinaryPath and verifies the result before committing to it.
+//
+// newBinaryPath must already live in the same directory as the running
+// executable so the renames below are atomic renames on a single
+// filesystem rather than cross-device copies. On success the previous
+// binary is removed; on any failure the previous binary is restored and an
+// error is returned.
+func ReplaceExecutable(newBinaryPath string) error {
+	targetPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolving running executable: %w", err)
+	}
+	targetPath, err = filepath.EvalSymlinks(targetPath)
+	if err != nil {
+		return fmt.Errorf("resolving executable symlink: %w", err)
+	}
+
+	if filepath.Dir(newBinaryPath) != filepath.Dir(targetPath) {
+		return fmt.Errorf("new binary %q must be staged in %q to guarantee an atomic replace", newBinaryPath, filepath.Dir(targetPath))
+	}
+
+	if err := os.Chmod(newBinaryPath, 0o755); err != nil {
+		return fmt.Errorf("marking new binary executable: %w", err)
+	}
+
+	oldPath := targetPath + ".old"
+	// best effort: a leftover .old from a previous failed update shouldn't
+	// block this one.
+	_ = os.Remove(oldPath)
+
+	if err := renameReplacing(targetPath, oldPath); err != nil {
+		return fmt.Errorf("moving current binary to %q: %w", oldPath, err)
+	}
+
+	if err := renameReplacing(newBinaryPath, targetPath); err != nil {
+		if rollbackErr := renameReplacing(oldPath, targetPath); rollbackErr != nil {
+			return fmt.Errorf("moving new binary into place: %w (rollback also failed: %v)", err, rollbackErr)
+		}
+		return fmt.Errorf("moving new binary into place: %w", err)
+	}
+
+	if err := verifyNewBinary(targetPath); err != nil {
+		if rollbackErr := renameReplacing(oldPath, targetPath); rollbackErr != nil {
+			return fmt.Errorf("new binary failed verification (%v) and rollback also failed: %w", err, rollbackErr)
+		}
+		return fmt.Errorf("new binary failed verification, rolled back to previous version: %w", err)
+	}
+
+	// best effort, like the cleanup above: the update is already installed
+	// and verified, and a leftover .old is removed by the next update.
+	_ = os.Remove(oldPath)
+	return nil
+}
+
+// verifyNewBinary runs the freshly installed binary with
+// PostUpdateVerifyFlag and fails if it doesn't exit cleanly within
+// VerifyTimeout.
+func verifyNewBinary(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), VerifyTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, PostUpdateVerifyFlag)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, out)
+	}
+	return nil
+}
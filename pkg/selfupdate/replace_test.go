@@ -0,0 +1,51 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package selfupdate
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// TestMain lets this test binary double as the "freshly installed binary"
+// verifyNewBinary execs in TestVerifyNewBinarySucceedsWithShortCircuit: when invoked with
+// PostUpdateVerifyFlag it exits 0 immediately instead of running the test
+// suite, mirroring the init() short-circuit cmd/compose wires up for real.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == PostUpdateVerifyFlag {
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestVerifyNewBinarySucceedsWithShortCircuit(t *testing.T) {
+	if err := verifyNewBinary(os.Args[0]); err != nil {
+		t.Fatalf("verifyNewBinary returned an error with the hidden-flag short-circuit in place: %v", err)
+	}
+}
+
+func TestVerifyNewBinaryFailsWithoutShortCircuit(t *testing.T) {
+	// Without something intercepting PostUpdateVerifyFlag before the
+	// binary's normal flag parsing, an unrecognized flag makes it exit
+	// non-zero — this is exactly the failure mode that made every update
+	// roll back before cmd/compose's init() fix.
+	cmd := exec.Command(os.Args[0], "--totally-unrecognized-flag")
+	if err := cmd.Run(); err == nil {
+		t.Fatal("expected the subprocess to exit non-zero on an unrecognized flag")
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package selfupdate
+
+import "os"
+
+// renameReplacing renames oldpath to newpath, replacing newpath if it
+// already exists. On POSIX systems this is just os.Rename: a running
+// executable can be unlinked/replaced while it's still executing.
+func renameReplacing(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
@@ -0,0 +1,37 @@
+//go:build windows
+
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package selfupdate
+
+import "golang.org/x/sys/windows"
+
+// renameReplacing renames oldpath to newpath, replacing newpath if it
+// already exists. Windows refuses to overwrite a running executable with a
+// plain rename, so this goes through MoveFileEx with
+// MOVEFILE_REPLACE_EXISTING instead.
+func renameReplacing(oldpath, newpath string) error {
+	oldPtr, err := windows.UTF16PtrFromString(oldpath)
+	if err != nil {
+		return err
+	}
+	newPtr, err := windows.UTF16PtrFromString(newpath)
+	if err != nil {
+		return err
+	}
+	return windows.MoveFileEx(oldPtr, newPtr, windows.MOVEFILE_REPLACE_EXISTING)
+}
@@ -0,0 +1,197 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package updatecheck implements the opt-in background update notifier:
+// once per compose invocation, if it's been more than Options.Interval
+// since the last poll, kick off a short-timeout check against GitHub and
+// print a one-line banner on the *next* exit if a newer version showed
+// up. A caller (the root compose command) is expected to check
+// --no-update-check / COMPOSE_DISABLE_UPDATE_CHECK, call Start near
+// startup, and invoke the returned function right before the process
+// exits.
+package updatecheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+)
+
+// State is persisted at $XDG_STATE_HOME/compose/update-check.json so the
+// next poll can be a cheap conditional GET instead of a full release
+// fetch.
+type State struct {
+	LatestVersion string    `json:"latest_version"`
+	ETag          string    `json:"etag,omitempty"`
+	CheckedAt     time.Time `json:"checked_at"`
+}
+
+// DefaultStatePath returns $XDG_STATE_HOME/compose/update-check.json,
+// falling back to ~/.local/state when XDG_STATE_HOME isn't set.
+func DefaultStatePath() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "compose", "update-check.json"), nil
+}
+
+// Options configures the background notifier.
+type Options struct {
+	// Disabled should be set from --no-update-check or
+	// COMPOSE_DISABLE_UPDATE_CHECK; Start also checks the env var itself
+	// so callers that forget don't accidentally phone home.
+	Disabled bool
+	// Repo is the GitHub repo polled for the latest release, e.g.
+	// "docker/compose".
+	Repo           string
+	CurrentVersion string
+	// Interval is the minimum time between checks; a few hours is
+	// typical so this never runs on every single invocation.
+	Interval  time.Duration
+	StatePath string
+}
+
+// Start checks State and, if it's stale, launches a background poll with
+// a short timeout. It returns a function the caller should invoke right
+// before the process exits: that function never blocks, so if the poll
+// hasn't finished yet, the banner is simply deferred to a later
+// invocation rather than delaying exit.
+func Start(opts Options) func() {
+	noop := func() {}
+	if opts.Disabled || os.Getenv("COMPOSE_DISABLE_UPDATE_CHECK") != "" {
+		return noop
+	}
+	if opts.StatePath == "" || opts.Repo == "" {
+		return noop
+	}
+
+	state := loadState(opts.StatePath)
+	if time.Since(state.CheckedAt) < opts.Interval {
+		return noop
+	}
+
+	result := make(chan string, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		result <- poll(ctx, opts.StatePath, opts.Repo, state)
+	}()
+
+	return func() {
+		select {
+		case latest := <-result:
+			if latest != "" && isNewer(latest, opts.CurrentVersion) {
+				fmt.Printf("A new version v%s is available — run `compose selfupdate`\n", latest)
+			}
+		default:
+			// Poll hasn't completed yet; skip silently rather than
+			// hold up exit.
+		}
+	}
+}
+
+// githubAPIBase is a var, not a const, so tests can point poll at an
+// httptest server instead of the real GitHub API.
+var githubAPIBase = "https://api.github.com"
+
+func poll(ctx context.Context, statePath, repo string, state State) string {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", githubAPIBase, repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return ""
+	}
+	if state.ETag != "" {
+		req.Header.Set("If-None-Match", state.ETag)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified {
+		state.CheckedAt = time.Now()
+		_ = saveState(statePath, state)
+		return state.LatestVersion
+	}
+	if res.StatusCode != http.StatusOK {
+		return ""
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&release); err != nil {
+		return ""
+	}
+
+	newState := State{
+		LatestVersion: strings.TrimPrefix(release.TagName, "v"),
+		ETag:          res.Header.Get("ETag"),
+		CheckedAt:     time.Now(),
+	}
+	_ = saveState(statePath, newState)
+
+	return newState.LatestVersion
+}
+
+func isNewer(latest, current string) bool {
+	latestVersion, err := semver.NewVersion(latest)
+	if err != nil {
+		return false
+	}
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return false
+	}
+	return latestVersion.GreaterThan(currentVersion)
+}
+
+func loadState(path string) State {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return State{}
+	}
+	var s State
+	if err := json.Unmarshal(content, &s); err != nil {
+		return State{}
+	}
+	return s
+}
+
+func saveState(path string, s State) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	content, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
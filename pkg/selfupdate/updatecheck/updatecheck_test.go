@@ -0,0 +1,104 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package updatecheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"2.25.0", "2.24.0", true},
+		{"2.24.0", "2.25.0", false},
+		{"2.24.0", "2.24.0", false},
+		{"not-a-version", "2.24.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := isNewer(tt.latest, tt.current); got != tt.want {
+			t.Errorf("isNewer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+// withTestGitHubAPI points poll at server for the duration of the test.
+func withTestGitHubAPI(t *testing.T, server *httptest.Server) {
+	t.Helper()
+	original := githubAPIBase
+	githubAPIBase = server.URL
+	t.Cleanup(func() { githubAPIBase = original })
+}
+
+func TestPollReturnsLatestVersionAndSavesState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		_, _ = w.Write([]byte(`{"tag_name": "v2.99.0"}`))
+	}))
+	defer server.Close()
+	withTestGitHubAPI(t, server)
+
+	statePath := filepath.Join(t.TempDir(), "update-check.json")
+	latest := poll(context.Background(), statePath, "docker/compose", State{})
+	if latest != "2.99.0" {
+		t.Errorf("poll = %q, want %q", latest, "2.99.0")
+	}
+
+	saved := loadState(statePath)
+	if saved.LatestVersion != "2.99.0" || saved.ETag != `"abc"` {
+		t.Errorf("saved state = %+v, want LatestVersion=2.99.0 ETag=\"abc\"", saved)
+	}
+}
+
+func TestPollReusesCachedVersionOnNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+	withTestGitHubAPI(t, server)
+
+	statePath := filepath.Join(t.TempDir(), "update-check.json")
+	latest := poll(context.Background(), statePath, "docker/compose", State{LatestVersion: "2.24.0", ETag: `"abc"`})
+	if latest != "2.24.0" {
+		t.Errorf("poll on 304 = %q, want cached version %q", latest, "2.24.0")
+	}
+}
+
+func TestPollReturnsEmptyOnServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	withTestGitHubAPI(t, server)
+
+	statePath := filepath.Join(t.TempDir(), "update-check.json")
+	if latest := poll(context.Background(), statePath, "docker/compose", State{}); latest != "" {
+		t.Errorf("poll on 500 = %q, want empty string", latest)
+	}
+}
+
+func TestLoadStateMissingFile(t *testing.T) {
+	if got := loadState(filepath.Join(t.TempDir(), "missing.json")); got != (State{}) {
+		t.Errorf("loadState of a missing file = %+v, want zero value", got)
+	}
+}
@@ -0,0 +1,38 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package verify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCosignVerifierKeylessFailsClosedWithoutPinnedIdentity(t *testing.T) {
+	v := CosignVerifier{Keyless: true}
+	err := v.Verify(context.Background(), []byte("binary"), []byte("sig"), []byte("cert"))
+	if err == nil {
+		t.Fatal("expected keyless verification without a pinned identity/issuer to fail closed, got nil error")
+	}
+}
+
+func TestCosignVerifierKeylessFailsClosedWithPartialIdentity(t *testing.T) {
+	v := CosignVerifier{Keyless: true, CertificateIdentity: "^https://github.com/docker/compose/.*$"}
+	err := v.Verify(context.Background(), []byte("binary"), []byte("sig"), []byte("cert"))
+	if err == nil {
+		t.Fatal("expected keyless verification missing the OIDC issuer to fail closed, got nil error")
+	}
+}
@@ -0,0 +1,58 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jedisct1/go-minisign"
+)
+
+// MinisignVerifier checks a minisign Ed25519 signature against a single
+// hardcoded trusted public key.
+type MinisignVerifier struct {
+	// TrustedKey is the base64-encoded minisign public key compose
+	// releases are signed with.
+	TrustedKey string
+}
+
+func (v MinisignVerifier) Verify(_ context.Context, binary, signature, _ []byte) error {
+	if v.TrustedKey == "" {
+		return fmt.Errorf("minisign verification requested but this build has no trusted key embedded")
+	}
+
+	publicKey, err := minisign.NewPublicKey(v.TrustedKey)
+	if err != nil {
+		return fmt.Errorf("parsing embedded minisign public key: %w", err)
+	}
+
+	sig, err := minisign.DecodeSignature(string(signature))
+	if err != nil {
+		return fmt.Errorf("parsing minisign signature: %w", err)
+	}
+
+	ok, err := publicKey.Verify(binary, sig)
+	if err != nil {
+		return fmt.Errorf("verifying minisign signature: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("minisign signature does not match the trusted key")
+	}
+
+	return nil
+}
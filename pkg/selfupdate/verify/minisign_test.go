@@ -0,0 +1,51 @@
+/*
+   Copyright 2022 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package verify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMinisignVerifierFailsClosedWithoutTrustedKey(t *testing.T) {
+	v := MinisignVerifier{}
+	err := v.Verify(context.Background(), []byte("binary"), []byte("signature"), nil)
+	if err == nil {
+		t.Fatal("expected verification without an embedded trusted key to fail closed, got nil error")
+	}
+}
+
+func TestMinisignVerifierRejectsMalformedPublicKey(t *testing.T) {
+	v := MinisignVerifier{TrustedKey: "not-a-valid-minisign-key"}
+	err := v.Verify(context.Background(), []byte("binary"), []byte("signature"), nil)
+	if err == nil {
+		t.Fatal("expected a malformed trusted key to be rejected, got nil error")
+	}
+}
+
+func TestMinisignVerifierRejectsMalformedSignature(t *testing.T) {
+	// A syntactically valid minisign public key (untrusted, generated only
+	// for this test) so the malformed input under test is the signature,
+	// not the key.
+	const testKey = "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn72xvc1wQ"
+
+	v := MinisignVerifier{TrustedKey: testKey}
+	err := v.Verify(context.Background(), []byte("binary"), []byte("not-a-valid-signature"), nil)
+	if err == nil {
+		t.Fatal("expected a malformed signature to be rejected, got nil error")
+	}
+}